@@ -0,0 +1,477 @@
+// Copyright 2015-2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// This file implements the reverse direction of converter.go: translating a
+// parsed docker-compose project into an ECS TaskDefinition that can be
+// passed to RegisterTaskDefinition, so a user can round-trip an existing
+// docker-compose.yml onto ECS.
+
+package converter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	composeV3 "github.com/docker/cli/cli/compose/types"
+	"github.com/pkg/errors"
+)
+
+// defaultContainerMemoryMiB is used when a compose service does not set
+// deploy.resources.limits.memory. ECS requires a memory limit on every container definition that
+// isn't covered by a task-level limit.
+const defaultContainerMemoryMiB = 512
+
+const miBInBytes = 1024 * 1024
+
+// convertToTaskDefinition translates a parsed docker-compose project into an
+// ECS TaskDefinition. A service with a build: directive or the swarm-only
+// "global" deploy mode is reported as an error; other compose fields with no
+// counterpart handled by convertToContainerDef are not converted and raise no
+// error.
+func convertToTaskDefinition(project *composeV3.Config) (*ecs.TaskDefinition, error) {
+	if project == nil {
+		return nil, errors.New("compose project is required")
+	}
+
+	volumes, bindVolumeNames := convertToECSVolumes(project)
+
+	taskDefinition := &ecs.TaskDefinition{Volumes: volumes}
+	for _, service := range project.Services {
+		containerDef, err := convertToContainerDef(service, bindVolumeNames)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert service %q to an ECS container definition", service.Name)
+		}
+
+		taskDefinition.ContainerDefinitions = append(taskDefinition.ContainerDefinitions, containerDef)
+	}
+
+	return taskDefinition, nil
+}
+
+// convertToECSVolumes produces the TaskDefinition-level Volumes a compose
+// project implies: one per top-level named volume (driver, driver_opts, and
+// labels preserved), plus one synthesized per distinct bind-mount host path
+// used by a service. Compose resolves a bind mount's host path inline on the
+// service, but ECS has no such concept -- every MountPoint.SourceVolume must
+// resolve to a Volume declared on the task definition, so bind mounts need a
+// stand-in Volume to reference. The returned map carries the generated name
+// for each bind mount host path, so convertToMountPoints can resolve it back.
+func convertToECSVolumes(project *composeV3.Config) ([]*ecs.Volume, map[string]string) {
+	var volumes []*ecs.Volume
+
+	for name, volumeConfig := range project.Volumes {
+		scope := ecs.ScopeTask
+		if volumeConfig.External.External {
+			scope = ecs.ScopeShared
+		}
+
+		volumes = append(volumes, &ecs.Volume{
+			Name: aws.String(name),
+			DockerVolumeConfiguration: &ecs.DockerVolumeConfiguration{
+				Scope:      aws.String(scope),
+				Driver:     aws.String(volumeConfig.Driver),
+				DriverOpts: convertToDockerLabelPointers(composeV3.Labels(volumeConfig.DriverOpts)),
+				Labels:     convertToDockerLabelPointers(volumeConfig.Labels),
+			},
+		})
+	}
+
+	bindVolumeNames := make(map[string]string)
+	for _, service := range project.Services {
+		for _, serviceVolume := range service.Volumes {
+			if serviceVolume.Type != "bind" {
+				continue
+			}
+			if _, ok := bindVolumeNames[serviceVolume.Source]; ok {
+				continue
+			}
+
+			name := fmt.Sprintf("bind-%d", len(bindVolumeNames))
+			bindVolumeNames[serviceVolume.Source] = name
+			volumes = append(volumes, &ecs.Volume{
+				Name: aws.String(name),
+				Host: &ecs.HostVolumeProperties{SourcePath: aws.String(serviceVolume.Source)},
+			})
+		}
+	}
+
+	return volumes, bindVolumeNames
+}
+
+// convertToContainerDef translates a single compose service into an ECS
+// container definition. bindVolumeNames resolves a bind-mount service
+// volume's host path to the stand-in Volume name synthesized for it by
+// convertToECSVolumes.
+func convertToContainerDef(service composeV3.ServiceConfig, bindVolumeNames map[string]string) (*ecs.ContainerDefinition, error) {
+	if service.Build.Context != "" || service.Build.Dockerfile != "" {
+		return nil, errors.Errorf("service %q uses build:, but ECS task definitions require a pre-built image", service.Name)
+	}
+	if service.Deploy.Mode == "global" {
+		return nil, errors.Errorf("service %q uses the swarm-only deploy mode %q, which ECS does not support", service.Name, service.Deploy.Mode)
+	}
+
+	containerDef := &ecs.ContainerDefinition{
+		Name:                   aws.String(service.Name),
+		Image:                  aws.String(service.Image),
+		Command:                aws.StringSlice([]string(service.Command)),
+		EntryPoint:             aws.StringSlice([]string(service.Entrypoint)),
+		WorkingDirectory:       aws.String(service.WorkingDir),
+		Hostname:               aws.String(service.Hostname),
+		Links:                  aws.StringSlice(service.Links),
+		DnsServers:             aws.StringSlice([]string(service.DNS)),
+		DnsSearchDomains:       aws.StringSlice([]string(service.DNSSearch)),
+		User:                   aws.String(service.User),
+		DockerSecurityOptions:  aws.StringSlice(service.SecurityOpt),
+		PseudoTerminal:         aws.Bool(service.Tty),
+		Privileged:             aws.Bool(service.Privileged),
+		ReadonlyRootFilesystem: aws.Bool(service.ReadOnly),
+		Memory:                 aws.Int64(convertToMemory(service.Deploy.Resources.Limits)),
+		Environment:            convertToKeyValuePairs(service.Environment),
+		PortMappings:           convertToECSPortMappings(service.Ports),
+		MountPoints:            convertToMountPoints(service.Volumes, bindVolumeNames),
+		DockerLabels:           convertToDockerLabelPointers(service.Labels),
+		HealthCheck:            convertToHealthCheck(service.HealthCheck),
+		LogConfiguration:       convertToLogConfiguration(service.Logging),
+		Ulimits:                convertToUlimits(service.Ulimits),
+	}
+
+	extraHosts, err := convertToExtraHosts([]string(service.ExtraHosts))
+	if err != nil {
+		return nil, err
+	}
+	containerDef.ExtraHosts = extraHosts
+
+	linuxParams, err := convertToLinuxParameters(service)
+	if err != nil {
+		return nil, err
+	}
+	containerDef.LinuxParameters = linuxParams
+
+	return containerDef, nil
+}
+
+// convertToMemory converts a compose deploy.resources.limits.memory (in
+// bytes; mem_limit itself is a compose v3 ForbiddenProperty superseded by
+// deploy.resources) to the MiB value ECS expects, falling back to
+// defaultContainerMemoryMiB when unset.
+func convertToMemory(limits *composeV3.Resource) int64 {
+	if limits == nil || limits.MemoryBytes == 0 {
+		return defaultContainerMemoryMiB
+	}
+
+	return int64(limits.MemoryBytes) / miBInBytes
+}
+
+// convertToKeyValuePairs converts a compose environment mapping into ECS
+// environment key/value pairs.
+func convertToKeyValuePairs(environment composeV3.MappingWithEquals) []*ecs.KeyValuePair {
+	out := make([]*ecs.KeyValuePair, 0, len(environment))
+	for name, value := range environment {
+		out = append(out, &ecs.KeyValuePair{
+			Name:  aws.String(name),
+			Value: value,
+		})
+	}
+
+	return out
+}
+
+// convertToECSPortMappings converts compose ports into ECS PortMappings.
+func convertToECSPortMappings(ports []composeV3.ServicePortConfig) []*ecs.PortMapping {
+	out := make([]*ecs.PortMapping, 0, len(ports))
+	for _, port := range ports {
+		portMapping := &ecs.PortMapping{
+			ContainerPort: aws.Int64(int64(port.Target)),
+			Protocol:      aws.String(port.Protocol),
+		}
+
+		if port.Published != 0 {
+			portMapping.HostPort = aws.Int64(int64(port.Published))
+		}
+
+		out = append(out, portMapping)
+	}
+
+	return out
+}
+
+// convertToMountPoints converts compose service volumes into ECS
+// MountPoints. For a "volume" entry, Source is already the named volume
+// declared on the task definition; for a "bind" entry, Source is a host
+// path, so it is resolved through bindVolumeNames to the stand-in Volume
+// convertToECSVolumes synthesized for it.
+func convertToMountPoints(volumes []composeV3.ServiceVolumeConfig, bindVolumeNames map[string]string) []*ecs.MountPoint {
+	out := make([]*ecs.MountPoint, 0, len(volumes))
+	for _, volume := range volumes {
+		sourceVolume := volume.Source
+		if volume.Type == "bind" {
+			sourceVolume = bindVolumeNames[volume.Source]
+		}
+
+		out = append(out, &ecs.MountPoint{
+			SourceVolume:  aws.String(sourceVolume),
+			ContainerPath: aws.String(volume.Target),
+			ReadOnly:      aws.Bool(volume.ReadOnly),
+		})
+	}
+
+	return out
+}
+
+// convertToExtraHosts converts compose "hostname:IP" entries into ECS
+// HostEntry values.
+func convertToExtraHosts(hosts []string) ([]*ecs.HostEntry, error) {
+	out := make([]*ecs.HostEntry, 0, len(hosts))
+	for _, host := range hosts {
+		parts := strings.SplitN(host, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("extra host %q must be in the form hostname:IP", host)
+		}
+
+		out = append(out, &ecs.HostEntry{
+			Hostname:  aws.String(parts[0]),
+			IpAddress: aws.String(parts[1]),
+		})
+	}
+
+	return out, nil
+}
+
+// convertToLogConfiguration converts a compose logging config into an ECS
+// LogConfiguration.
+func convertToLogConfiguration(logging *composeV3.LoggingConfig) *ecs.LogConfiguration {
+	if logging == nil {
+		return nil
+	}
+
+	options := make(map[string]*string, len(logging.Options))
+	for k, v := range logging.Options {
+		options[k] = aws.String(v)
+	}
+
+	return &ecs.LogConfiguration{
+		LogDriver: aws.String(logging.Driver),
+		Options:   options,
+	}
+}
+
+// convertToUlimits converts compose ulimits into ECS Ulimits.
+func convertToUlimits(ulimits map[string]*composeV3.UlimitsConfig) []*ecs.Ulimit {
+	out := make([]*ecs.Ulimit, 0, len(ulimits))
+	for name, ulimit := range ulimits {
+		out = append(out, &ecs.Ulimit{
+			Name:      aws.String(name),
+			SoftLimit: aws.Int64(int64(ulimit.Soft)),
+			HardLimit: aws.Int64(int64(ulimit.Hard)),
+		})
+	}
+
+	return out
+}
+
+// convertToDockerLabelPointers converts compose labels into the
+// map[string]*string shape ECS uses for DockerLabels.
+func convertToDockerLabelPointers(labels composeV3.Labels) map[string]*string {
+	out := make(map[string]*string, len(labels))
+	for k, v := range labels {
+		out[k] = aws.String(v)
+	}
+
+	return out
+}
+
+// convertToHealthCheck converts a compose healthcheck into an ECS
+// HealthCheck.
+func convertToHealthCheck(healthCheck *composeV3.HealthCheckConfig) *ecs.HealthCheck {
+	if healthCheck == nil {
+		return nil
+	}
+
+	out := &ecs.HealthCheck{
+		Command: aws.StringSlice(healthCheck.Test),
+	}
+
+	if healthCheck.Retries != nil {
+		out.Retries = aws.Int64(int64(*healthCheck.Retries))
+	}
+	if healthCheck.Interval != nil {
+		out.Interval = aws.Int64(int64(time.Duration(*healthCheck.Interval) / time.Second))
+	}
+	if healthCheck.Timeout != nil {
+		out.Timeout = aws.Int64(int64(time.Duration(*healthCheck.Timeout) / time.Second))
+	}
+	if healthCheck.StartPeriod != nil {
+		out.StartPeriod = aws.Int64(int64(time.Duration(*healthCheck.StartPeriod) / time.Second))
+	}
+
+	return out
+}
+
+// convertToLinuxParameters converts the compose fields that map onto ECS's
+// LinuxParameters: cap_add/cap_drop, devices, tmpfs, init, and shm_size.
+func convertToLinuxParameters(service composeV3.ServiceConfig) (*ecs.LinuxParameters, error) {
+	if len(service.CapAdd) == 0 && len(service.CapDrop) == 0 && len(service.Devices) == 0 &&
+		len(service.Tmpfs) == 0 && service.Init == nil && service.ShmSize == "" {
+		return nil, nil
+	}
+
+	linuxParams := &ecs.LinuxParameters{
+		InitProcessEnabled: service.Init,
+	}
+
+	if len(service.CapAdd) > 0 || len(service.CapDrop) > 0 {
+		linuxParams.Capabilities = &ecs.KernelCapabilities{
+			Add:  aws.StringSlice(service.CapAdd),
+			Drop: aws.StringSlice(service.CapDrop),
+		}
+	}
+
+	devices, err := convertToDevices(service.Devices)
+	if err != nil {
+		return nil, err
+	}
+	linuxParams.Devices = devices
+
+	tmpfs, err := convertToECSTmpfs([]string(service.Tmpfs))
+	if err != nil {
+		return nil, err
+	}
+	linuxParams.Tmpfs = tmpfs
+
+	if service.ShmSize != "" {
+		size, err := convertToMiB(string(service.ShmSize))
+		if err != nil {
+			return nil, err
+		}
+		linuxParams.SharedMemorySize = aws.Int64(size)
+	}
+
+	return linuxParams, nil
+}
+
+// convertToDevices parses compose "hostPath[:containerPath[:permissions]]"
+// device strings into ECS Devices.
+func convertToDevices(devices []string) ([]*ecs.Device, error) {
+	out := make([]*ecs.Device, 0, len(devices))
+	for _, device := range devices {
+		parts := strings.Split(device, ":")
+		if parts[0] == "" {
+			return nil, errors.Errorf("device %q must specify a host path", device)
+		}
+
+		ecsDevice := &ecs.Device{HostPath: aws.String(parts[0])}
+		if len(parts) > 1 {
+			ecsDevice.ContainerPath = aws.String(parts[1])
+		}
+		if len(parts) > 2 {
+			permissions, err := convertToDevicePermissions(parts[2])
+			if err != nil {
+				return nil, err
+			}
+			ecsDevice.Permissions = permissions
+		}
+
+		out = append(out, ecsDevice)
+	}
+
+	return out, nil
+}
+
+func convertToDevicePermissions(permissions string) ([]*string, error) {
+	out := make([]*string, 0, len(permissions))
+	for _, permission := range permissions {
+		switch permission {
+		case 'r':
+			out = append(out, aws.String(ecs.DeviceCgroupPermissionRead))
+		case 'w':
+			out = append(out, aws.String(ecs.DeviceCgroupPermissionWrite))
+		case 'm':
+			out = append(out, aws.String(ecs.DeviceCgroupPermissionMknod))
+		default:
+			return nil, errors.Errorf("unknown device permission %q", string(permission))
+		}
+	}
+
+	return out, nil
+}
+
+// convertToECSTmpfs parses compose "path:size=64MiB,rw,noexec" tmpfs
+// strings into ECS Tmpfs.
+func convertToECSTmpfs(tmpfsList []string) ([]*ecs.Tmpfs, error) {
+	out := make([]*ecs.Tmpfs, 0, len(tmpfsList))
+	for _, entry := range tmpfsList {
+		parts := strings.SplitN(entry, ":", 2)
+		path := parts[0]
+		if path == "" {
+			return nil, errors.Errorf("tmpfs entry %q must specify a container path", entry)
+		}
+
+		ecsTmpfs := &ecs.Tmpfs{ContainerPath: aws.String(path)}
+		if len(parts) > 1 {
+			var options []string
+			for _, opt := range strings.Split(parts[1], ",") {
+				if strings.HasPrefix(opt, "size=") {
+					size, err := convertToMiB(strings.TrimPrefix(opt, "size="))
+					if err != nil {
+						return nil, err
+					}
+					ecsTmpfs.Size = aws.Int64(size)
+					continue
+				}
+				options = append(options, opt)
+			}
+			ecsTmpfs.MountOptions = aws.StringSlice(options)
+		}
+
+		if ecsTmpfs.Size == nil {
+			return nil, errors.Errorf("tmpfs entry %q is missing a size", entry)
+		}
+
+		out = append(out, ecsTmpfs)
+	}
+
+	return out, nil
+}
+
+// convertToMiB parses a docker compose size string (e.g. "64MiB", "1GiB")
+// into a MiB value.
+func convertToMiB(size string) (int64, error) {
+	size = strings.TrimSpace(size)
+
+	switch {
+	case strings.HasSuffix(size, "GiB"):
+		value, err := strconv.ParseInt(strings.TrimSuffix(size, "GiB"), 10, 64)
+		if err != nil {
+			return 0, errors.Errorf("invalid size %q", size)
+		}
+		return value * 1024, nil
+	case strings.HasSuffix(size, "MiB"):
+		value, err := strconv.ParseInt(strings.TrimSuffix(size, "MiB"), 10, 64)
+		if err != nil {
+			return 0, errors.Errorf("invalid size %q", size)
+		}
+		return value, nil
+	case strings.HasSuffix(size, "KiB"):
+		value, err := strconv.ParseInt(strings.TrimSuffix(size, "KiB"), 10, 64)
+		if err != nil {
+			return 0, errors.Errorf("invalid size %q", size)
+		}
+		return value / 1024, nil
+	default:
+		return 0, errors.Errorf("unsupported size format %q, expected a value like \"64MiB\" or \"1GiB\"", size)
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2015-2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package converter
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	composeV3 "github.com/docker/cli/cli/compose/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertToContainerDef(t *testing.T) {
+	service := composeV3.ServiceConfig{
+		Name:  "web",
+		Image: "nginx",
+		Ports: []composeV3.ServicePortConfig{
+			{Target: 80, Published: 8080, Protocol: "tcp"},
+		},
+		Environment: composeV3.MappingWithEquals{
+			"rails_env": aws.String("development"),
+		},
+	}
+
+	containerDef, err := convertToContainerDef(service, nil)
+	assert.NoError(t, err, "Unexpected error converting ServiceConfig")
+	assert.Equal(t, "web", aws.StringValue(containerDef.Name))
+	assert.Equal(t, "nginx", aws.StringValue(containerDef.Image))
+	assert.Equal(t, int64(defaultContainerMemoryMiB), aws.Int64Value(containerDef.Memory))
+	assert.Equal(t, []*ecs.PortMapping{
+		{
+			ContainerPort: aws.Int64(80),
+			HostPort:      aws.Int64(8080),
+			Protocol:      aws.String("tcp"),
+		},
+	}, containerDef.PortMappings)
+	assert.Equal(t, []*ecs.KeyValuePair{
+		{Name: aws.String("rails_env"), Value: aws.String("development")},
+	}, containerDef.Environment)
+}
+
+func TestConvertToContainerDef_ErrorsOnBuild(t *testing.T) {
+	service := composeV3.ServiceConfig{
+		Name:  "web",
+		Build: composeV3.BuildConfig{Context: "."},
+	}
+
+	_, err := convertToContainerDef(service, nil)
+	assert.Error(t, err)
+}
+
+func TestConvertToContainerDef_ErrorsOnSwarmDeployMode(t *testing.T) {
+	service := composeV3.ServiceConfig{
+		Name:   "web",
+		Deploy: composeV3.DeployConfig{Mode: "global"},
+	}
+
+	_, err := convertToContainerDef(service, nil)
+	assert.Error(t, err)
+}
+
+func TestConvertToMemory(t *testing.T) {
+	assert.Equal(t, int64(defaultContainerMemoryMiB), convertToMemory(nil))
+	assert.Equal(t, int64(defaultContainerMemoryMiB), convertToMemory(&composeV3.Resource{}))
+	assert.Equal(t, int64(256), convertToMemory(&composeV3.Resource{MemoryBytes: composeV3.UnitBytes(256 * miBInBytes)}))
+}
+
+func TestConvertToECSPortMappings(t *testing.T) {
+	input := []composeV3.ServicePortConfig{
+		{Target: 80, Protocol: "tcp"},
+		{Target: 8080, Published: 80, Protocol: "tcp"},
+		{Target: 53, Published: 53, Protocol: "udp"},
+	}
+
+	expected := []*ecs.PortMapping{
+		{ContainerPort: aws.Int64(80), Protocol: aws.String("tcp")},
+		{ContainerPort: aws.Int64(8080), HostPort: aws.Int64(80), Protocol: aws.String("tcp")},
+		{ContainerPort: aws.Int64(53), HostPort: aws.Int64(53), Protocol: aws.String("udp")},
+	}
+
+	actual := convertToECSPortMappings(input)
+	assert.Equal(t, expected, actual)
+}
+
+func TestConvertToMountPoints(t *testing.T) {
+	input := []composeV3.ServiceVolumeConfig{
+		{Type: "volume", Source: "data", Target: "/var/lib/data", ReadOnly: true},
+		{Type: "bind", Source: "/host/logs", Target: "/var/log"},
+	}
+	bindVolumeNames := map[string]string{"/host/logs": "bind-0"}
+
+	expected := []*ecs.MountPoint{
+		{
+			SourceVolume:  aws.String("data"),
+			ContainerPath: aws.String("/var/lib/data"),
+			ReadOnly:      aws.Bool(true),
+		},
+		{
+			SourceVolume:  aws.String("bind-0"),
+			ContainerPath: aws.String("/var/log"),
+			ReadOnly:      aws.Bool(false),
+		},
+	}
+
+	actual := convertToMountPoints(input, bindVolumeNames)
+	assert.Equal(t, expected, actual)
+}
+
+func TestConvertToECSVolumes(t *testing.T) {
+	project := &composeV3.Config{
+		Volumes: map[string]composeV3.VolumeConfig{
+			"data": {Name: "data", Driver: "local"},
+		},
+		Services: []composeV3.ServiceConfig{
+			{
+				Name: "web",
+				Volumes: []composeV3.ServiceVolumeConfig{
+					{Type: "volume", Source: "data", Target: "/var/lib/data"},
+					{Type: "bind", Source: "/host/logs", Target: "/var/log"},
+				},
+			},
+			{
+				Name: "worker",
+				Volumes: []composeV3.ServiceVolumeConfig{
+					{Type: "bind", Source: "/host/logs", Target: "/var/log"},
+				},
+			},
+		},
+	}
+
+	volumes, bindVolumeNames := convertToECSVolumes(project)
+
+	assert.Len(t, volumes, 2, "expected one named volume and one deduplicated bind volume")
+	assert.Equal(t, "bind-0", bindVolumeNames["/host/logs"])
+
+	var bindVolume, namedVolume *ecs.Volume
+	for _, volume := range volumes {
+		switch aws.StringValue(volume.Name) {
+		case "data":
+			namedVolume = volume
+		case "bind-0":
+			bindVolume = volume
+		}
+	}
+
+	if assert.NotNil(t, namedVolume) {
+		assert.Equal(t, "local", aws.StringValue(namedVolume.DockerVolumeConfiguration.Driver))
+		assert.Equal(t, ecs.ScopeTask, aws.StringValue(namedVolume.DockerVolumeConfiguration.Scope))
+	}
+	if assert.NotNil(t, bindVolume) {
+		assert.Equal(t, "/host/logs", aws.StringValue(bindVolume.Host.SourcePath))
+	}
+}
+
+func TestConvertToTaskDefinition(t *testing.T) {
+	project := &composeV3.Config{
+		Volumes: map[string]composeV3.VolumeConfig{
+			"data": {Name: "data", Driver: "local"},
+		},
+		Services: []composeV3.ServiceConfig{
+			{
+				Name:  "web",
+				Image: "nginx",
+				Volumes: []composeV3.ServiceVolumeConfig{
+					{Type: "volume", Source: "data", Target: "/var/lib/data"},
+					{Type: "bind", Source: "/host/logs", Target: "/var/log"},
+				},
+			},
+		},
+	}
+
+	taskDefinition, err := convertToTaskDefinition(project)
+	assert.NoError(t, err, "Unexpected error converting compose project")
+	assert.Len(t, taskDefinition.Volumes, 2)
+
+	require.Len(t, taskDefinition.ContainerDefinitions, 1)
+	mountPoints := taskDefinition.ContainerDefinitions[0].MountPoints
+	require.Len(t, mountPoints, 2)
+	assert.Equal(t, "data", aws.StringValue(mountPoints[0].SourceVolume))
+
+	bindMountSource := aws.StringValue(mountPoints[1].SourceVolume)
+	var bindVolumeDeclared bool
+	for _, volume := range taskDefinition.Volumes {
+		if aws.StringValue(volume.Name) == bindMountSource {
+			bindVolumeDeclared = true
+			assert.Equal(t, "/host/logs", aws.StringValue(volume.Host.SourcePath))
+		}
+	}
+	assert.True(t, bindVolumeDeclared, "mount point must reference a volume declared on the task definition")
+}
+
+func TestConvertToTaskDefinition_ErrorsOnNilProject(t *testing.T) {
+	_, err := convertToTaskDefinition(nil)
+	assert.Error(t, err)
+}
+
+func TestConvertToExtraHosts(t *testing.T) {
+	input := []string{"somehost:162.242.195.82"}
+	expected := []*ecs.HostEntry{
+		{Hostname: aws.String("somehost"), IpAddress: aws.String("162.242.195.82")},
+	}
+
+	actual, err := convertToExtraHosts(input)
+	assert.NoError(t, err, "Unexpected error converting ExtraHosts")
+	assert.Equal(t, expected, actual)
+}
+
+func TestConvertToExtraHosts_ErrorsOnMalformedEntry(t *testing.T) {
+	_, err := convertToExtraHosts([]string{"somehost"})
+	assert.Error(t, err)
+}
+
+func TestConvertToLogConfiguration(t *testing.T) {
+	input := &composeV3.LoggingConfig{
+		Driver: "awslogs",
+		Options: map[string]string{
+			"awslogs-group": "/ecs/fargate-task-definition",
+		},
+	}
+
+	expected := &ecs.LogConfiguration{
+		LogDriver: aws.String("awslogs"),
+		Options: map[string]*string{
+			"awslogs-group": aws.String("/ecs/fargate-task-definition"),
+		},
+	}
+
+	actual := convertToLogConfiguration(input)
+	assert.Equal(t, expected, actual)
+}
+
+func TestConvertToUlimits(t *testing.T) {
+	input := map[string]*composeV3.UlimitsConfig{
+		"nofile": {Soft: 2000, Hard: 4000},
+	}
+
+	actual := convertToUlimits(input)
+	assert.Equal(t, []*ecs.Ulimit{
+		{Name: aws.String("nofile"), SoftLimit: aws.Int64(2000), HardLimit: aws.Int64(4000)},
+	}, actual)
+}
+
+func TestConvertToLinuxParameters(t *testing.T) {
+	service := composeV3.ServiceConfig{
+		CapAdd:  []string{"NET_ADMIN"},
+		CapDrop: []string{"KILL"},
+		Devices: []string{"/dev/sda:/dev/xvdc:r"},
+		Tmpfs:   composeV3.StringList{"/run:size=64MiB,rw,noexec"},
+		Init:    aws.Bool(true),
+		ShmSize: "128MiB",
+	}
+
+	linuxParams, err := convertToLinuxParameters(service)
+	assert.NoError(t, err, "Unexpected error converting LinuxParameters")
+	assert.Equal(t, []*string{aws.String("NET_ADMIN")}, linuxParams.Capabilities.Add)
+	assert.Equal(t, []*string{aws.String("KILL")}, linuxParams.Capabilities.Drop)
+	assert.Equal(t, aws.Bool(true), linuxParams.InitProcessEnabled)
+	assert.Equal(t, aws.Int64(128), linuxParams.SharedMemorySize)
+	assert.Equal(t, []*ecs.Device{
+		{
+			HostPath:      aws.String("/dev/sda"),
+			ContainerPath: aws.String("/dev/xvdc"),
+			Permissions:   []*string{aws.String(ecs.DeviceCgroupPermissionRead)},
+		},
+	}, linuxParams.Devices)
+	assert.Equal(t, []*ecs.Tmpfs{
+		{
+			ContainerPath: aws.String("/run"),
+			Size:          aws.Int64(64),
+			MountOptions:  aws.StringSlice([]string{"rw", "noexec"}),
+		},
+	}, linuxParams.Tmpfs)
+}
+
+func TestConvertToMiB(t *testing.T) {
+	cases := map[string]int64{
+		"64MiB":   64,
+		"1GiB":    1024,
+		"1024KiB": 1,
+	}
+
+	for input, expected := range cases {
+		actual, err := convertToMiB(input)
+		assert.NoError(t, err, "Unexpected error converting size %q", input)
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestConvertToMiB_ErrorsOnUnsupportedFormat(t *testing.T) {
+	_, err := convertToMiB("64")
+	assert.Error(t, err)
+}
@@ -0,0 +1,835 @@
+// Copyright 2015-2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package converter implements the logic to translate an ecs.TaskDefinition
+// structure to a docker compose schema, which will be written to a
+// docker-compose.local.yml file.
+
+package converter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	composeV3 "github.com/docker/cli/cli/compose/types"
+	"github.com/pkg/errors"
+)
+
+// convertToComposeService translates a single ECS container definition into
+// the equivalent docker compose service configuration. taskDefinition is the
+// owning task definition, used to resolve the SourceVolume referenced by
+// each of the container's MountPoints and to derive network/proxy settings
+// shared by every container in the task. taskDefinition may be nil.
+func convertToComposeService(taskDefinition *ecs.TaskDefinition, containerDef *ecs.ContainerDefinition) (composeV3.ServiceConfig, error) {
+	service := composeV3.ServiceConfig{
+		Name:        aws.StringValue(containerDef.Name),
+		Image:       aws.StringValue(containerDef.Image),
+		Command:     composeV3.ShellCommand(aws.StringValueSlice(containerDef.Command)),
+		Entrypoint:  composeV3.ShellCommand(aws.StringValueSlice(containerDef.EntryPoint)),
+		WorkingDir:  aws.StringValue(containerDef.WorkingDirectory),
+		Hostname:    aws.StringValue(containerDef.Hostname),
+		Links:       aws.StringValueSlice(containerDef.Links),
+		DNS:         composeV3.StringList(aws.StringValueSlice(containerDef.DnsServers)),
+		DNSSearch:   composeV3.StringList(aws.StringValueSlice(containerDef.DnsSearchDomains)),
+		User:        aws.StringValue(containerDef.User),
+		SecurityOpt: aws.StringValueSlice(containerDef.DockerSecurityOptions),
+		Tty:         aws.BoolValue(containerDef.PseudoTerminal),
+		Privileged:  aws.BoolValue(containerDef.Privileged),
+		ReadOnly:    aws.BoolValue(containerDef.ReadonlyRootFilesystem),
+		Environment: composeV3.MappingWithEquals(convertEnvironment(containerDef.Environment)),
+		ExtraHosts:  composeV3.HostsList(convertExtraHosts(containerDef.ExtraHosts)),
+		HealthCheck: convertHealthCheck(containerDef.HealthCheck),
+		Labels:      convertDockerLabels(containerDef.DockerLabels),
+		Logging:     convertLogging(containerDef.LogConfiguration),
+	}
+
+	if service.Logging == nil {
+		service.Logging = convertFirelensConfiguration(containerDef.FirelensConfiguration)
+	}
+
+	ulimits, err := convertUlimits(containerDef.Ulimits)
+	if err != nil {
+		return service, err
+	}
+	service.Ulimits = ulimits
+
+	ports, err := convertToPortMappings(containerDef.PortMappings)
+	if err != nil {
+		return service, err
+	}
+	service.Ports = ports
+
+	if linuxParams := containerDef.LinuxParameters; linuxParams != nil {
+		tmpfs, err := convertToTmpfs(linuxParams.Tmpfs)
+		if err != nil {
+			return service, err
+		}
+		service.Tmpfs = composeV3.StringList(tmpfs)
+
+		devices, err := convertDevices(linuxParams.Devices)
+		if err != nil {
+			return service, err
+		}
+		service.Devices = devices
+
+		service.Init = linuxParams.InitProcessEnabled
+		service.ShmSize = convertShmSize(linuxParams.SharedMemorySize)
+		service.CapAdd = convertCapAdd(linuxParams.Capabilities)
+		service.CapDrop = convertCapDrop(linuxParams.Capabilities)
+	}
+
+	var taskVolumes []*ecs.Volume
+	var networkMode string
+	var proxyConfig *ecs.ProxyConfiguration
+	if taskDefinition != nil {
+		taskVolumes = taskDefinition.Volumes
+		networkMode = aws.StringValue(taskDefinition.NetworkMode)
+		proxyConfig = taskDefinition.ProxyConfiguration
+	}
+
+	namedVolumes, bindSources, err := convertToVolumes(taskVolumes)
+	if err != nil {
+		return service, err
+	}
+
+	volumes, err := convertToServiceVolumes(containerDef.MountPoints, namedVolumes, bindSources)
+	if err != nil {
+		return service, err
+	}
+	service.Volumes = volumes
+
+	if volumesFrom := convertToVolumesFrom(containerDef.VolumesFrom); len(volumesFrom) > 0 {
+		if service.Labels == nil {
+			service.Labels = composeV3.Labels{}
+		}
+		service.Labels[volumesFromLabel] = strings.Join(volumesFrom, ",")
+	}
+
+	service.Deploy = convertToDeploy(containerDef)
+
+	dependsOn, err := convertToDependsOn(containerDef.DependsOn)
+	if err != nil {
+		return service, err
+	}
+	dependsOn = append(dependsOn, convertToProxyDependsOn(proxyConfig, containerDef.Name)...)
+	service.DependsOn = dependsOn
+
+	service.Secrets = convertToServiceSecrets(containerDef.Secrets)
+	service.Sysctls = convertToSysctls(containerDef.SystemControls)
+
+	mode, networks, err := convertNetworkMode(networkMode, containerDef)
+	if err != nil {
+		return service, err
+	}
+	service.NetworkMode = mode
+	service.Networks = networks
+
+	return service, nil
+}
+
+// convertToComposeProject translates an ECS TaskDefinition into a full
+// docker compose project: one service per container definition, plus the
+// top-level volumes, secrets, and networks sections those services
+// reference, and an Envoy sidecar service when the task has App Mesh proxy
+// configuration.
+func convertToComposeProject(taskDefinition *ecs.TaskDefinition) (*composeV3.Config, error) {
+	if taskDefinition == nil {
+		return nil, errors.New("task definition is required")
+	}
+
+	project := &composeV3.Config{}
+	for _, containerDef := range taskDefinition.ContainerDefinitions {
+		service, err := convertToComposeService(taskDefinition, containerDef)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert container definition %q to a compose service", aws.StringValue(containerDef.Name))
+		}
+		project.Services = append(project.Services, service)
+	}
+
+	namedVolumes, _, err := convertToVolumes(taskDefinition.Volumes)
+	if err != nil {
+		return nil, err
+	}
+	if len(namedVolumes) > 0 {
+		project.Volumes = namedVolumes
+	}
+
+	var secrets []*ecs.Secret
+	for _, containerDef := range taskDefinition.ContainerDefinitions {
+		secrets = append(secrets, containerDef.Secrets...)
+	}
+	if secretConfigs := convertToSecretConfigs(secrets); len(secretConfigs) > 0 {
+		project.Secrets = secretConfigs
+	}
+
+	if networks := convertToTopLevelNetworks(aws.StringValue(taskDefinition.NetworkMode)); len(networks) > 0 {
+		project.Networks = networks
+	}
+
+	if !hasContainerDefinition(taskDefinition.ContainerDefinitions, taskDefinition.ProxyConfiguration) {
+		if proxyService := convertToProxyService(taskDefinition.ProxyConfiguration); proxyService != nil {
+			project.Services = append(project.Services, *proxyService)
+		}
+	}
+
+	return project, nil
+}
+
+// hasContainerDefinition reports whether the task already declares a
+// container named after the proxy's ContainerName. App Mesh proxy
+// containers are often declared explicitly by the user with their own
+// image/version, in which case convertToProxyService must not also
+// synthesize a stub with the same name -- compose rejects a services list
+// with two entries sharing a Name.
+func hasContainerDefinition(containerDefs []*ecs.ContainerDefinition, proxyConfig *ecs.ProxyConfiguration) bool {
+	if proxyConfig == nil {
+		return false
+	}
+
+	proxyContainerName := aws.StringValue(proxyConfig.ContainerName)
+	for _, containerDef := range containerDefs {
+		if aws.StringValue(containerDef.Name) == proxyContainerName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// convertToDeploy translates ECS Cpu/Memory/MemoryReservation into the
+// compose deploy resources block. Cpu is given in ECS CPU units (1024 per
+// vCPU) and is translated to the compose fractional-core string; Memory and
+// MemoryReservation are given in MiB and translated to bytes. DeployConfig is
+// a plain struct on ServiceConfig, not a pointer, so the zero value is
+// returned rather than nil when there is nothing to set.
+func convertToDeploy(containerDef *ecs.ContainerDefinition) composeV3.DeployConfig {
+	limits := convertToResource(containerDef.Cpu, containerDef.Memory)
+	reservations := convertToResource(nil, containerDef.MemoryReservation)
+
+	if limits == nil && reservations == nil {
+		return composeV3.DeployConfig{}
+	}
+
+	return composeV3.DeployConfig{
+		Resources: composeV3.Resources{
+			Limits:       limits,
+			Reservations: reservations,
+		},
+	}
+}
+
+func convertToResource(cpu *int64, memory *int64) *composeV3.Resource {
+	if cpu == nil && memory == nil {
+		return nil
+	}
+
+	resource := &composeV3.Resource{}
+	if cpu != nil {
+		resource.NanoCPUs = convertCPUToString(aws.Int64Value(cpu))
+	}
+	if memory != nil {
+		resource.MemoryBytes = composeV3.UnitBytes(aws.Int64Value(memory) * miBInBytes)
+	}
+
+	return resource
+}
+
+// convertCPUToString converts ECS CPU units (1024 units == 1 vCPU) into the
+// compose fractional-core string, e.g. 512 -> "0.5".
+func convertCPUToString(cpuUnits int64) string {
+	return strconv.FormatFloat(float64(cpuUnits)/1024, 'f', -1, 64)
+}
+
+// ecsDependencyConditionsWithNoComposeEquivalent lists the ECS container
+// dependency conditions that depends_on cannot approximate at all: compose's
+// ServiceConfig.DependsOn is a plain []string that only orders container
+// start, so there is no long-syntax condition map (as there is in later
+// compose-spec versions) to carry START/HEALTHY onto, and no way at all to
+// express waiting on a container's exit code for SUCCESS/COMPLETE.
+var ecsDependencyConditionsWithNoComposeEquivalent = map[string]bool{
+	ecs.ContainerConditionSuccess:  true,
+	ecs.ContainerConditionComplete: true,
+}
+
+// convertToDependsOn translates ECS ContainerDependency entries into the
+// compose depends_on list. The ECS Condition itself is not preserved (START
+// and HEALTHY both collapse onto "start this container first"); conditions
+// with no depends_on approximation at all are rejected.
+func convertToDependsOn(dependencies []*ecs.ContainerDependency) ([]string, error) {
+	if len(dependencies) == 0 {
+		return nil, nil
+	}
+
+	out := make([]string, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		condition := aws.StringValue(dependency.Condition)
+		if ecsDependencyConditionsWithNoComposeEquivalent[condition] {
+			return nil, errors.Errorf("container dependency condition %q on container %q has no compose equivalent", condition, aws.StringValue(dependency.ContainerName))
+		}
+
+		out = append(out, aws.StringValue(dependency.ContainerName))
+	}
+
+	return out, nil
+}
+
+// convertToServiceSecrets builds the per-service compose secrets
+// references. Pair with convertToSecretConfigs to build the matching
+// top-level "secrets:" section.
+func convertToServiceSecrets(secrets []*ecs.Secret) []composeV3.ServiceSecretConfig {
+	out := make([]composeV3.ServiceSecretConfig, 0, len(secrets))
+	for _, secret := range secrets {
+		out = append(out, composeV3.ServiceSecretConfig{Source: aws.StringValue(secret.Name)})
+	}
+
+	return out
+}
+
+// convertToSecretConfigs produces the top-level compose "secrets:" section
+// from a container's Secrets. A Secrets Manager ARN or an SSM Parameter
+// Store name (either a full ARN or a bare parameter name/path, which always
+// starts with "/") is declared external (resolved by the docker CLI secrets
+// driver at run time); anything else is treated as a local file path.
+func convertToSecretConfigs(secrets []*ecs.Secret) map[string]composeV3.SecretConfig {
+	out := make(map[string]composeV3.SecretConfig, len(secrets))
+	for _, secret := range secrets {
+		name := aws.StringValue(secret.Name)
+		valueFrom := aws.StringValue(secret.ValueFrom)
+
+		secretConfig := composeV3.SecretConfig{Name: name}
+		if strings.HasPrefix(valueFrom, "arn:") || strings.HasPrefix(valueFrom, "/") {
+			secretConfig.External = composeV3.External{External: true}
+		} else {
+			secretConfig.File = valueFrom
+		}
+
+		out[name] = secretConfig
+	}
+
+	return out
+}
+
+// convertToSysctls converts ECS SystemControls into the compose sysctls
+// mapping.
+func convertToSysctls(systemControls []*ecs.SystemControl) map[string]string {
+	out := make(map[string]string, len(systemControls))
+	for _, systemControl := range systemControls {
+		out[aws.StringValue(systemControl.Namespace)] = aws.StringValue(systemControl.Value)
+	}
+
+	return out
+}
+
+// convertToVolumes produces the top-level compose "volumes:" section from a
+// task definition's Volumes. It returns two maps keyed by volume name: named
+// volumes (backed by a docker volume driver, to be declared in the compose
+// file's top-level volumes section) and bind mount source paths (which
+// compose resolves inline on the service and never declares at the top
+// level).
+func convertToVolumes(taskVolumes []*ecs.Volume) (map[string]composeV3.VolumeConfig, map[string]string, error) {
+	namedVolumes := make(map[string]composeV3.VolumeConfig)
+	bindSources := make(map[string]string)
+
+	for _, volume := range taskVolumes {
+		name := aws.StringValue(volume.Name)
+		if name == "" {
+			return nil, nil, errors.New("name is required on a volume")
+		}
+
+		switch {
+		case volume.Host != nil:
+			bindSources[name] = aws.StringValue(volume.Host.SourcePath)
+		case volume.DockerVolumeConfiguration != nil:
+			dockerVolume := volume.DockerVolumeConfiguration
+			driverOpts := make(map[string]string)
+			for k, v := range dockerVolume.DriverOpts {
+				driverOpts[k] = aws.StringValue(v)
+			}
+
+			namedVolumes[name] = composeV3.VolumeConfig{
+				Name:       name,
+				Driver:     aws.StringValue(dockerVolume.Driver),
+				DriverOpts: driverOpts,
+				Labels:     convertDockerLabels(dockerVolume.Labels),
+				External:   composeV3.External{External: aws.StringValue(dockerVolume.Scope) == ecs.ScopeShared},
+			}
+		default:
+			// No Host or DockerVolumeConfiguration is a plain, anonymous
+			// docker-managed volume scoped to the task.
+			namedVolumes[name] = composeV3.VolumeConfig{Name: name}
+		}
+	}
+
+	return namedVolumes, bindSources, nil
+}
+
+// convertToServiceVolumes converts a container's MountPoints into compose
+// service volume entries, resolving each SourceVolume against the task's
+// bind mount sources. A MountPoint referencing an undeclared SourceVolume is
+// an error.
+func convertToServiceVolumes(mountPoints []*ecs.MountPoint, namedVolumes map[string]composeV3.VolumeConfig, bindSources map[string]string) ([]composeV3.ServiceVolumeConfig, error) {
+	out := make([]composeV3.ServiceVolumeConfig, 0, len(mountPoints))
+	for _, mountPoint := range mountPoints {
+		sourceVolume := aws.StringValue(mountPoint.SourceVolume)
+		containerPath := aws.StringValue(mountPoint.ContainerPath)
+		if containerPath == "" {
+			return nil, errors.New("containerPath is required on a mountPoint")
+		}
+
+		serviceVolume := composeV3.ServiceVolumeConfig{
+			Target:   containerPath,
+			ReadOnly: aws.BoolValue(mountPoint.ReadOnly),
+		}
+
+		if hostPath, ok := bindSources[sourceVolume]; ok {
+			serviceVolume.Type = "bind"
+			serviceVolume.Source = hostPath
+		} else if _, ok := namedVolumes[sourceVolume]; ok {
+			serviceVolume.Type = "volume"
+			serviceVolume.Source = sourceVolume
+		} else {
+			return nil, errors.Errorf("mountPoint references source volume %q which is not defined on the task definition", sourceVolume)
+		}
+
+		out = append(out, serviceVolume)
+	}
+
+	return out, nil
+}
+
+// volumesFromLabel carries a container's VolumesFrom entries as a label,
+// since compose v3's ServiceConfig has no volumes_from field: libcompose
+// lists it under ForbiddenProperties, directing users to share a named
+// top-level volume instead. That rewrite isn't something this converter can
+// do automatically without knowing the other container's mounts, so the
+// original relationship is preserved here for a human to act on.
+const volumesFromLabel = "com.amazonaws.ecs.local.volumes-from"
+
+// convertToVolumesFrom converts ECS VolumesFrom into the compose
+// "container[:ro]" shorthand.
+func convertToVolumesFrom(volumesFrom []*ecs.VolumeFrom) []string {
+	out := make([]string, 0, len(volumesFrom))
+	for _, volumeFrom := range volumesFrom {
+		entry := aws.StringValue(volumeFrom.SourceContainer)
+		if aws.BoolValue(volumeFrom.ReadOnly) {
+			entry = entry + ":ro"
+		}
+
+		out = append(out, entry)
+	}
+
+	return out
+}
+
+// convertToPortMappings translates the PortMappings on an ECS container
+// definition into the docker compose ports format. A HostPort of 0 (or
+// unset) is treated as "publish an ephemeral port without a fixed host
+// binding", matching the ECS dynamic host port behavior. A ContainerPortRange
+// is expanded into one ServicePortConfig per port in the range.
+func convertToPortMappings(portMappings []*ecs.PortMapping) ([]composeV3.ServicePortConfig, error) {
+	servicePorts := make([]composeV3.ServicePortConfig, 0, len(portMappings))
+	for _, portMapping := range portMappings {
+		protocol := aws.StringValue(portMapping.Protocol)
+		if protocol == "" {
+			protocol = ecs.TransportProtocolTcp
+		}
+
+		if containerPortRange := aws.StringValue(portMapping.ContainerPortRange); containerPortRange != "" {
+			expanded, err := convertToPortMappingRange(containerPortRange, protocol)
+			if err != nil {
+				return nil, err
+			}
+			servicePorts = append(servicePorts, expanded...)
+			continue
+		}
+
+		containerPort := aws.Int64Value(portMapping.ContainerPort)
+		if containerPort <= 0 {
+			return nil, errors.New("containerPort is required on a portMapping")
+		}
+
+		servicePorts = append(servicePorts, composeV3.ServicePortConfig{
+			Target:    uint32(containerPort),
+			Published: uint32(aws.Int64Value(portMapping.HostPort)),
+			Protocol:  protocol,
+		})
+	}
+
+	return servicePorts, nil
+}
+
+// convertToPortMappingRange expands an ECS containerPortRange (e.g.
+// "8000-8002") into one ServicePortConfig per port. ecs.PortMapping carries
+// no corresponding host range field on the task definition (hostPortRange
+// only appears at runtime on NetworkBinding, once the agent has picked
+// ports), so every expanded port is left unpublished, matching how a bridge
+// ContainerPort without a HostPort is handled above.
+func convertToPortMappingRange(containerPortRange, protocol string) ([]composeV3.ServicePortConfig, error) {
+	containerStart, containerEnd, err := parsePortRange(containerPortRange)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid containerPortRange %q", containerPortRange)
+	}
+
+	out := make([]composeV3.ServicePortConfig, 0, containerEnd-containerStart+1)
+	for port := containerStart; port <= containerEnd; port++ {
+		out = append(out, composeV3.ServicePortConfig{
+			Target:   uint32(port),
+			Protocol: protocol,
+		})
+	}
+
+	return out, nil
+}
+
+// parsePortRange parses an ECS "start-end" port range string, e.g. "8000-8002".
+func parsePortRange(portRange string) (int64, int64, error) {
+	parts := strings.SplitN(portRange, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, errors.Errorf("port range %q must be in the form \"start-end\"", portRange)
+	}
+
+	start, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, errors.Errorf("invalid start port %q", parts[0])
+	}
+	end, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, errors.Errorf("invalid end port %q", parts[1])
+	}
+	if start > end {
+		return 0, 0, errors.Errorf("start port %d is greater than end port %d", start, end)
+	}
+
+	return start, end, nil
+}
+
+// convertToTmpfs converts the LinuxParameters.Tmpfs field on an ECS
+// container definition into the compose tmpfs string format, e.g.
+// "/run:size=64MiB,rw,noexec,nosuid".
+func convertToTmpfs(tmpfsList []*ecs.Tmpfs) ([]string, error) {
+	out := make([]string, 0, len(tmpfsList))
+	for _, tmpfs := range tmpfsList {
+		path := aws.StringValue(tmpfs.ContainerPath)
+		if path == "" {
+			return nil, errors.New("containerPath is required on a tmpfs mount")
+		}
+
+		size := aws.Int64Value(tmpfs.Size)
+		if size == 0 {
+			return nil, errors.New("size is required on a tmpfs mount")
+		}
+
+		entry := fmt.Sprintf("%s:size=%s", path, convertToDockerSizeString(size))
+		if options := aws.StringValueSlice(tmpfs.MountOptions); len(options) > 0 {
+			entry = entry + "," + strings.Join(options, ",")
+		}
+
+		out = append(out, entry)
+	}
+
+	return out, nil
+}
+
+// convertUlimits converts ECS Ulimits into their compose equivalent.
+func convertUlimits(ulimits []*ecs.Ulimit) (map[string]*composeV3.UlimitsConfig, error) {
+	out := make(map[string]*composeV3.UlimitsConfig)
+	for _, ulimit := range ulimits {
+		name := aws.StringValue(ulimit.Name)
+		if name == "" {
+			return nil, errors.New("name is required on a ulimit")
+		}
+
+		out[name] = &composeV3.UlimitsConfig{
+			Soft: int(aws.Int64Value(ulimit.SoftLimit)),
+			Hard: int(aws.Int64Value(ulimit.HardLimit)),
+		}
+	}
+
+	return out, nil
+}
+
+// convertDevices converts ECS Devices into the compose "hostPath[:containerPath[:permissions]]" format.
+func convertDevices(devices []*ecs.Device) ([]string, error) {
+	out := make([]string, 0, len(devices))
+	for _, device := range devices {
+		hostPath := aws.StringValue(device.HostPath)
+		if hostPath == "" {
+			return nil, errors.New("hostPath is required on a device")
+		}
+
+		entry := hostPath
+		if containerPath := aws.StringValue(device.ContainerPath); containerPath != "" {
+			entry = entry + ":" + containerPath
+		}
+
+		if permissions := convertDevicePermissions(device.Permissions); permissions != "" {
+			entry = entry + ":" + permissions
+		}
+
+		out = append(out, entry)
+	}
+
+	return out, nil
+}
+
+func convertDevicePermissions(permissions []*string) string {
+	var sb strings.Builder
+	for _, permission := range permissions {
+		switch aws.StringValue(permission) {
+		case ecs.DeviceCgroupPermissionRead:
+			sb.WriteString("r")
+		case ecs.DeviceCgroupPermissionWrite:
+			sb.WriteString("w")
+		case ecs.DeviceCgroupPermissionMknod:
+			sb.WriteString("m")
+		}
+	}
+
+	return sb.String()
+}
+
+// convertShmSize converts the ECS SharedMemorySize (given in MiB) into the
+// compose shm_size string format, e.g. "128MiB" or "1GiB".
+func convertShmSize(shmSize *int64) string {
+	if shmSize == nil {
+		return ""
+	}
+
+	return convertToDockerSizeString(aws.Int64Value(shmSize))
+}
+
+// convertToDockerSizeString converts a size given in MiB into the compose
+// size string format, preferring GiB when the value divides evenly.
+func convertToDockerSizeString(sizeInMiB int64) string {
+	if sizeInMiB != 0 && sizeInMiB%1024 == 0 {
+		return fmt.Sprintf("%dGiB", sizeInMiB/1024)
+	}
+
+	return fmt.Sprintf("%dMiB", sizeInMiB)
+}
+
+func convertCapAdd(capabilities *ecs.KernelCapabilities) []string {
+	if capabilities == nil {
+		return nil
+	}
+
+	return aws.StringValueSlice(capabilities.Add)
+}
+
+func convertCapDrop(capabilities *ecs.KernelCapabilities) []string {
+	if capabilities == nil {
+		return nil
+	}
+
+	return aws.StringValueSlice(capabilities.Drop)
+}
+
+func convertDockerLabels(labels map[string]*string) composeV3.Labels {
+	out := make(composeV3.Labels)
+	for k, v := range labels {
+		out[k] = aws.StringValue(v)
+	}
+
+	return out
+}
+
+func convertEnvironment(kvPairs []*ecs.KeyValuePair) map[string]*string {
+	out := make(map[string]*string)
+	for _, kv := range kvPairs {
+		out[aws.StringValue(kv.Name)] = kv.Value
+	}
+
+	return out
+}
+
+func convertExtraHosts(hostEntries []*ecs.HostEntry) []string {
+	out := make([]string, 0, len(hostEntries))
+	for _, host := range hostEntries {
+		out = append(out, fmt.Sprintf("%s:%s", aws.StringValue(host.Hostname), aws.StringValue(host.IpAddress)))
+	}
+
+	return out
+}
+
+func convertHealthCheck(healthCheck *ecs.HealthCheck) *composeV3.HealthCheckConfig {
+	if healthCheck == nil {
+		return nil
+	}
+
+	out := &composeV3.HealthCheckConfig{
+		Test: aws.StringValueSlice(healthCheck.Command),
+	}
+
+	if healthCheck.Retries != nil {
+		retries := uint64(aws.Int64Value(healthCheck.Retries))
+		out.Retries = &retries
+	}
+	if healthCheck.Interval != nil {
+		interval := composeV3.Duration(time.Duration(aws.Int64Value(healthCheck.Interval)) * time.Second)
+		out.Interval = &interval
+	}
+	if healthCheck.Timeout != nil {
+		timeout := composeV3.Duration(time.Duration(aws.Int64Value(healthCheck.Timeout)) * time.Second)
+		out.Timeout = &timeout
+	}
+	if healthCheck.StartPeriod != nil {
+		startPeriod := composeV3.Duration(time.Duration(aws.Int64Value(healthCheck.StartPeriod)) * time.Second)
+		out.StartPeriod = &startPeriod
+	}
+
+	return out
+}
+
+func convertLogging(logConfig *ecs.LogConfiguration) *composeV3.LoggingConfig {
+	if logConfig == nil {
+		return nil
+	}
+
+	options := make(map[string]string)
+	for k, v := range logConfig.Options {
+		options[k] = aws.StringValue(v)
+	}
+
+	return &composeV3.LoggingConfig{
+		Driver:  aws.StringValue(logConfig.LogDriver),
+		Options: options,
+	}
+}
+
+// convertFirelensConfiguration converts a container's FireLens log router
+// configuration into the same LoggingConfig shape convertLogging produces.
+// ECS keeps FirelensConfiguration and LogConfiguration as separate fields
+// (the former lives on the dedicated log router container, the latter on
+// the application container that forwards logs to it with LogDriver
+// "awsfirelens"), but compose has only one "logging:" block per service, so
+// this is only consulted when the container has no LogConfiguration of its
+// own.
+func convertFirelensConfiguration(firelensConfig *ecs.FirelensConfiguration) *composeV3.LoggingConfig {
+	if firelensConfig == nil {
+		return nil
+	}
+
+	options := make(map[string]string, len(firelensConfig.Options))
+	for k, v := range firelensConfig.Options {
+		options[k] = aws.StringValue(v)
+	}
+
+	return &composeV3.LoggingConfig{
+		Driver:  aws.StringValue(firelensConfig.Type),
+		Options: options,
+	}
+}
+
+// awsvpcNetworkName is the name of the user-defined bridge network used to
+// emulate an awsvpc task's single shared network namespace: every
+// container in the task joins it so they can reach each other the same way
+// they would over the task's ENI.
+const awsvpcNetworkName = "awsvpc"
+
+// convertNetworkMode inspects a task definition's NetworkMode and returns
+// the compose service's network_mode (used for the host and none modes,
+// which compose models as a service-level setting rather than a named
+// network) together with the named-network attachment used to emulate
+// awsvpc, if any.
+func convertNetworkMode(networkMode string, containerDef *ecs.ContainerDefinition) (string, map[string]*composeV3.ServiceNetworkConfig, error) {
+	switch networkMode {
+	case "", ecs.NetworkModeBridge:
+		return "", nil, nil
+	case ecs.NetworkModeHost:
+		return "host", nil, nil
+	case ecs.NetworkModeNone:
+		return "none", nil, nil
+	case ecs.NetworkModeAwsvpc:
+		aliases := append([]string{aws.StringValue(containerDef.Name)}, aws.StringValueSlice(containerDef.Links)...)
+		return "", map[string]*composeV3.ServiceNetworkConfig{
+			awsvpcNetworkName: {Aliases: aliases},
+		}, nil
+	default:
+		return "", nil, errors.Errorf("unsupported network mode %q", networkMode)
+	}
+}
+
+// convertToTopLevelNetworks returns the top-level compose "networks:"
+// section needed to emulate an awsvpc task's shared network namespace.
+// Other network modes need no top-level declaration: bridge is compose's
+// implicit default, and host/none are expressed via network_mode instead.
+func convertToTopLevelNetworks(networkMode string) map[string]composeV3.NetworkConfig {
+	if networkMode != ecs.NetworkModeAwsvpc {
+		return nil
+	}
+
+	return map[string]composeV3.NetworkConfig{
+		awsvpcNetworkName: {Driver: "bridge"},
+	}
+}
+
+const (
+	// appMeshProxyServiceName is the default name given to the synthesized
+	// App Mesh proxy sidecar service when a task uses ProxyConfiguration
+	// but does not name its proxy container.
+	appMeshProxyServiceName = "envoy"
+	appMeshEnvoyImage       = "amazon/aws-appmesh-envoy:latest"
+)
+
+// proxyServiceName returns the name the synthesized (or task-declared) App
+// Mesh proxy container is known by, so the stub service and the depends_on
+// entries pointing at it always agree. It defaults to appMeshProxyServiceName
+// only when the task definition leaves ContainerName unset.
+func proxyServiceName(proxyConfig *ecs.ProxyConfiguration) string {
+	if name := aws.StringValue(proxyConfig.ContainerName); name != "" {
+		return name
+	}
+	return appMeshProxyServiceName
+}
+
+// convertToProxyService builds the Envoy/App Mesh sidecar stub implied by a
+// task definition's ProxyConfiguration, so the local compose run mirrors
+// the mesh topology ECS would configure. The proxy's free-form Properties
+// (egress ports, ignored UID/GID, etc.) are passed through as environment
+// variables, matching how the ECS agent configures the proxy container.
+func convertToProxyService(proxyConfig *ecs.ProxyConfiguration) *composeV3.ServiceConfig {
+	if proxyConfig == nil {
+		return nil
+	}
+
+	environment := make(composeV3.MappingWithEquals, len(proxyConfig.Properties))
+	for _, kv := range proxyConfig.Properties {
+		environment[aws.StringValue(kv.Name)] = kv.Value
+	}
+
+	return &composeV3.ServiceConfig{
+		Name:        proxyServiceName(proxyConfig),
+		Image:       appMeshEnvoyImage,
+		Environment: environment,
+	}
+}
+
+// convertToProxyDependsOn makes an application container in the task depend
+// on the App Mesh proxy sidecar, so compose starts the proxy first. It
+// returns nil for the proxy container itself and for tasks with no
+// ProxyConfiguration.
+func convertToProxyDependsOn(proxyConfig *ecs.ProxyConfiguration, containerName *string) []string {
+	if proxyConfig == nil {
+		return nil
+	}
+
+	if aws.StringValue(containerName) == aws.StringValue(proxyConfig.ContainerName) {
+		return nil
+	}
+
+	return []string{proxyServiceName(proxyConfig)}
+}
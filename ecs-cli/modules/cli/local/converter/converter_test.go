@@ -154,7 +154,7 @@ func TestConvertToComposeService(t *testing.T) {
 	containerDef := taskDefinition.ContainerDefinitions[0]
 
 	// WHEN
-	service, err := convertToComposeService(containerDef)
+	service, err := convertToComposeService(nil, containerDef)
 
 	// THEN
 	assert.NoError(t, err, "Unexpected error converting Container Definition")
@@ -383,9 +383,9 @@ func TestConvertHealthCheck(t *testing.T) {
 		StartPeriod: aws.Int64(40),
 	}
 
-	interval := time.Duration(90) * time.Second
-	timeout := time.Duration(10) * time.Second
-	startPeriod := time.Duration(40) * time.Second
+	interval := composeV3.Duration(time.Duration(90) * time.Second)
+	timeout := composeV3.Duration(time.Duration(10) * time.Second)
+	startPeriod := composeV3.Duration(time.Duration(40) * time.Second)
 	retries := uint64(3)
 
 	expected := &composeV3.HealthCheckConfig{
@@ -400,6 +400,74 @@ func TestConvertHealthCheck(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestConvertToPortMappings(t *testing.T) {
+	expected := []composeV3.ServicePortConfig{
+		{
+			Target:    80,
+			Published: 0,
+			Protocol:  "tcp",
+		},
+		{
+			Target:    8080,
+			Published: 80,
+			Protocol:  "tcp",
+		},
+		{
+			Target:    53,
+			Published: 53,
+			Protocol:  "udp",
+		},
+	}
+
+	input := []*ecs.PortMapping{
+		{
+			ContainerPort: aws.Int64(80),
+		},
+		{
+			ContainerPort: aws.Int64(8080),
+			HostPort:      aws.Int64(80),
+		},
+		{
+			ContainerPort: aws.Int64(53),
+			HostPort:      aws.Int64(53),
+			Protocol:      aws.String("udp"),
+		},
+	}
+
+	actual, err := convertToPortMappings(input)
+	assert.NoError(t, err, "Unexpected error converting PortMappings")
+	assert.ElementsMatch(t, expected, actual)
+}
+
+func TestConvertToPortMappings_Range(t *testing.T) {
+	expected := []composeV3.ServicePortConfig{
+		{Target: 8000, Protocol: "tcp"},
+		{Target: 8001, Protocol: "tcp"},
+		{Target: 8002, Protocol: "tcp"},
+	}
+
+	input := []*ecs.PortMapping{
+		{
+			ContainerPortRange: aws.String("8000-8002"),
+		},
+	}
+
+	actual, err := convertToPortMappings(input)
+	assert.NoError(t, err, "Unexpected error converting PortMappings")
+	assert.ElementsMatch(t, expected, actual)
+}
+
+func TestConvertToPortMappings_ErrorsIfNoContainerPort(t *testing.T) {
+	input := []*ecs.PortMapping{
+		{
+			HostPort: aws.Int64(80),
+		},
+	}
+
+	_, err := convertToPortMappings(input)
+	assert.Error(t, err)
+}
+
 func TestConvertLogging(t *testing.T) {
 	input := &ecs.LogConfiguration{
 		LogDriver: aws.String("awslogs"),
@@ -422,4 +490,506 @@ func TestConvertLogging(t *testing.T) {
 	actual := convertLogging(input)
 
 	assert.Equal(t, expected, actual)
-}
\ No newline at end of file
+}
+
+func TestConvertFirelensConfiguration(t *testing.T) {
+	input := &ecs.FirelensConfiguration{
+		Type: aws.String(ecs.FirelensConfigurationTypeFluentbit),
+		Options: map[string]*string{
+			"enable-ecs-log-metadata": aws.String("true"),
+		},
+	}
+
+	expected := &composeV3.LoggingConfig{
+		Driver: ecs.FirelensConfigurationTypeFluentbit,
+		Options: map[string]string{
+			"enable-ecs-log-metadata": "true",
+		},
+	}
+
+	actual := convertFirelensConfiguration(input)
+	assert.Equal(t, expected, actual)
+}
+
+func TestConvertFirelensConfiguration_NilWhenUnset(t *testing.T) {
+	assert.Nil(t, convertFirelensConfiguration(nil))
+}
+
+func TestConvertToComposeService_PrefersLogConfigurationOverFirelens(t *testing.T) {
+	containerDef := &ecs.ContainerDefinition{
+		Name: aws.String("web"),
+		LogConfiguration: &ecs.LogConfiguration{
+			LogDriver: aws.String("awslogs"),
+		},
+		FirelensConfiguration: &ecs.FirelensConfiguration{
+			Type: aws.String(ecs.FirelensConfigurationTypeFluentbit),
+		},
+	}
+
+	service, err := convertToComposeService(nil, containerDef)
+	assert.NoError(t, err, "Unexpected error converting ContainerDefinition")
+	assert.Equal(t, "awslogs", service.Logging.Driver)
+}
+
+func TestConvertToComposeService_FirelensConfiguration(t *testing.T) {
+	containerDef := &ecs.ContainerDefinition{
+		Name: aws.String("log-router"),
+		FirelensConfiguration: &ecs.FirelensConfiguration{
+			Type: aws.String(ecs.FirelensConfigurationTypeFluentbit),
+			Options: map[string]*string{
+				"enable-ecs-log-metadata": aws.String("true"),
+			},
+		},
+	}
+
+	service, err := convertToComposeService(nil, containerDef)
+	assert.NoError(t, err, "Unexpected error converting ContainerDefinition")
+	assert.Equal(t, &composeV3.LoggingConfig{
+		Driver:  ecs.FirelensConfigurationTypeFluentbit,
+		Options: map[string]string{"enable-ecs-log-metadata": "true"},
+	}, service.Logging)
+}
+
+func TestConvertToVolumes(t *testing.T) {
+	input := []*ecs.Volume{
+		{
+			Name: aws.String("bind-vol"),
+			Host: &ecs.HostVolumeProperties{
+				SourcePath: aws.String("/ecs/data"),
+			},
+		},
+		{
+			Name: aws.String("named-vol"),
+			DockerVolumeConfiguration: &ecs.DockerVolumeConfiguration{
+				Driver: aws.String("local"),
+				DriverOpts: map[string]*string{
+					"type": aws.String("nfs"),
+				},
+				Labels: map[string]*string{
+					"foo": aws.String("bar"),
+				},
+				Scope: aws.String(ecs.ScopeShared),
+			},
+		},
+	}
+
+	namedVolumes, bindSources, err := convertToVolumes(input)
+	assert.NoError(t, err, "Unexpected error converting Volumes")
+
+	assert.Equal(t, map[string]string{"bind-vol": "/ecs/data"}, bindSources)
+	assert.Equal(t, composeV3.VolumeConfig{
+		Name:       "named-vol",
+		Driver:     "local",
+		DriverOpts: map[string]string{"type": "nfs"},
+		Labels:     composeV3.Labels{"foo": "bar"},
+		External:   composeV3.External{External: true},
+	}, namedVolumes["named-vol"])
+}
+
+func TestConvertToVolumes_ErrorsIfNoName(t *testing.T) {
+	input := []*ecs.Volume{
+		{
+			Host: &ecs.HostVolumeProperties{
+				SourcePath: aws.String("/ecs/data"),
+			},
+		},
+	}
+
+	_, _, err := convertToVolumes(input)
+	assert.Error(t, err)
+}
+
+func TestConvertToServiceVolumes(t *testing.T) {
+	namedVolumes := map[string]composeV3.VolumeConfig{
+		"named-vol": {Name: "named-vol"},
+	}
+	bindSources := map[string]string{
+		"bind-vol": "/ecs/data",
+	}
+
+	input := []*ecs.MountPoint{
+		{
+			SourceVolume:  aws.String("bind-vol"),
+			ContainerPath: aws.String("/var/data"),
+		},
+		{
+			SourceVolume:  aws.String("named-vol"),
+			ContainerPath: aws.String("/var/lib/data"),
+			ReadOnly:      aws.Bool(true),
+		},
+	}
+
+	expected := []composeV3.ServiceVolumeConfig{
+		{
+			Type:   "bind",
+			Source: "/ecs/data",
+			Target: "/var/data",
+		},
+		{
+			Type:     "volume",
+			Source:   "named-vol",
+			Target:   "/var/lib/data",
+			ReadOnly: true,
+		},
+	}
+
+	actual, err := convertToServiceVolumes(input, namedVolumes, bindSources)
+	assert.NoError(t, err, "Unexpected error converting MountPoints")
+	assert.ElementsMatch(t, expected, actual)
+}
+
+func TestConvertToServiceVolumes_ErrorsIfSourceVolumeNotFound(t *testing.T) {
+	input := []*ecs.MountPoint{
+		{
+			SourceVolume:  aws.String("does-not-exist"),
+			ContainerPath: aws.String("/var/data"),
+		},
+	}
+
+	_, err := convertToServiceVolumes(input, map[string]composeV3.VolumeConfig{}, map[string]string{})
+	assert.Error(t, err)
+}
+
+func TestConvertToDeploy(t *testing.T) {
+	containerDef := &ecs.ContainerDefinition{
+		Cpu:               aws.Int64(512),
+		Memory:            aws.Int64(256),
+		MemoryReservation: aws.Int64(128),
+	}
+
+	expected := composeV3.DeployConfig{
+		Resources: composeV3.Resources{
+			Limits: &composeV3.Resource{
+				NanoCPUs:    "0.5",
+				MemoryBytes: composeV3.UnitBytes(256 * 1024 * 1024),
+			},
+			Reservations: &composeV3.Resource{
+				MemoryBytes: composeV3.UnitBytes(128 * 1024 * 1024),
+			},
+		},
+	}
+
+	actual := convertToDeploy(containerDef)
+	assert.Equal(t, expected, actual)
+}
+
+func TestConvertToDeploy_ZeroValueWhenUnset(t *testing.T) {
+	actual := convertToDeploy(&ecs.ContainerDefinition{})
+	assert.Equal(t, composeV3.DeployConfig{}, actual)
+}
+
+func TestConvertToDependsOn(t *testing.T) {
+	input := []*ecs.ContainerDependency{
+		{
+			ContainerName: aws.String("db"),
+			Condition:     aws.String(ecs.ContainerConditionHealthy),
+		},
+		{
+			ContainerName: aws.String("init"),
+			Condition:     aws.String(ecs.ContainerConditionStart),
+		},
+	}
+
+	expected := []string{"db", "init"}
+
+	actual, err := convertToDependsOn(input)
+	assert.NoError(t, err, "Unexpected error converting DependsOn")
+	assert.Equal(t, expected, actual)
+}
+
+func TestConvertToDependsOn_ErrorsOnUnsupportedCondition(t *testing.T) {
+	input := []*ecs.ContainerDependency{
+		{
+			ContainerName: aws.String("db"),
+			Condition:     aws.String(ecs.ContainerConditionSuccess),
+		},
+	}
+
+	_, err := convertToDependsOn(input)
+	assert.Error(t, err)
+}
+
+func TestConvertToServiceSecrets(t *testing.T) {
+	input := []*ecs.Secret{
+		{
+			Name:      aws.String("db_password"),
+			ValueFrom: aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:db_password"),
+		},
+	}
+
+	expected := []composeV3.ServiceSecretConfig{
+		{Source: "db_password"},
+	}
+
+	actual := convertToServiceSecrets(input)
+	assert.Equal(t, expected, actual)
+}
+
+func TestConvertToSecretConfigs(t *testing.T) {
+	input := []*ecs.Secret{
+		{
+			Name:      aws.String("db_password"),
+			ValueFrom: aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:db_password"),
+		},
+		{
+			Name:      aws.String("local_secret"),
+			ValueFrom: aws.String("./secrets/local_secret"),
+		},
+		{
+			Name:      aws.String("ssm_secret"),
+			ValueFrom: aws.String("/my/app/db-password"),
+		},
+	}
+
+	expected := map[string]composeV3.SecretConfig{
+		"db_password": {
+			Name:     "db_password",
+			External: composeV3.External{External: true},
+		},
+		"local_secret": {
+			Name: "local_secret",
+			File: "./secrets/local_secret",
+		},
+		"ssm_secret": {
+			Name:     "ssm_secret",
+			External: composeV3.External{External: true},
+		},
+	}
+
+	actual := convertToSecretConfigs(input)
+	assert.Equal(t, expected, actual)
+}
+
+func TestConvertToSysctls(t *testing.T) {
+	input := []*ecs.SystemControl{
+		{
+			Namespace: aws.String("net.core.somaxconn"),
+			Value:     aws.String("1024"),
+		},
+	}
+
+	expected := map[string]string{
+		"net.core.somaxconn": "1024",
+	}
+
+	actual := convertToSysctls(input)
+	assert.Equal(t, expected, actual)
+}
+
+func TestConvertNetworkMode_Bridge(t *testing.T) {
+	mode, networks, err := convertNetworkMode(ecs.NetworkModeBridge, &ecs.ContainerDefinition{})
+	assert.NoError(t, err, "Unexpected error converting NetworkMode")
+	assert.Equal(t, "", mode)
+	assert.Nil(t, networks)
+}
+
+func TestConvertNetworkMode_Host(t *testing.T) {
+	mode, networks, err := convertNetworkMode(ecs.NetworkModeHost, &ecs.ContainerDefinition{})
+	assert.NoError(t, err, "Unexpected error converting NetworkMode")
+	assert.Equal(t, "host", mode)
+	assert.Nil(t, networks)
+}
+
+func TestConvertNetworkMode_None(t *testing.T) {
+	mode, networks, err := convertNetworkMode(ecs.NetworkModeNone, &ecs.ContainerDefinition{})
+	assert.NoError(t, err, "Unexpected error converting NetworkMode")
+	assert.Equal(t, "none", mode)
+	assert.Nil(t, networks)
+}
+
+func TestConvertNetworkMode_Awsvpc(t *testing.T) {
+	containerDef := &ecs.ContainerDefinition{
+		Name:  aws.String("web"),
+		Links: aws.StringSlice([]string{"cache"}),
+	}
+
+	mode, networks, err := convertNetworkMode(ecs.NetworkModeAwsvpc, containerDef)
+	assert.NoError(t, err, "Unexpected error converting NetworkMode")
+	assert.Equal(t, "", mode)
+	assert.Equal(t, map[string]*composeV3.ServiceNetworkConfig{
+		awsvpcNetworkName: {Aliases: []string{"web", "cache"}},
+	}, networks)
+}
+
+func TestConvertNetworkMode_ErrorsOnUnsupportedMode(t *testing.T) {
+	_, _, err := convertNetworkMode("none-such-mode", &ecs.ContainerDefinition{})
+	assert.Error(t, err)
+}
+
+func TestConvertToTopLevelNetworks(t *testing.T) {
+	assert.Nil(t, convertToTopLevelNetworks(ecs.NetworkModeBridge))
+	assert.Equal(t, map[string]composeV3.NetworkConfig{
+		awsvpcNetworkName: {Driver: "bridge"},
+	}, convertToTopLevelNetworks(ecs.NetworkModeAwsvpc))
+}
+
+func TestConvertToProxyService(t *testing.T) {
+	proxyConfig := &ecs.ProxyConfiguration{
+		ContainerName: aws.String("envoy"),
+		Properties: []*ecs.KeyValuePair{
+			{Name: aws.String("IgnoredUID"), Value: aws.String("1337")},
+			{Name: aws.String("ProxyIngressPort"), Value: aws.String("15000")},
+		},
+	}
+
+	service := convertToProxyService(proxyConfig)
+	assert.Equal(t, appMeshProxyServiceName, service.Name)
+	assert.Equal(t, appMeshEnvoyImage, service.Image)
+	assert.Equal(t, composeV3.MappingWithEquals{
+		"IgnoredUID":       aws.String("1337"),
+		"ProxyIngressPort": aws.String("15000"),
+	}, service.Environment)
+}
+
+func TestConvertToProxyService_NilWhenUnset(t *testing.T) {
+	assert.Nil(t, convertToProxyService(nil))
+}
+
+func TestConvertToProxyService_CustomContainerName(t *testing.T) {
+	proxyConfig := &ecs.ProxyConfiguration{ContainerName: aws.String("app-mesh-proxy")}
+
+	service := convertToProxyService(proxyConfig)
+	assert.Equal(t, "app-mesh-proxy", service.Name)
+}
+
+func TestConvertToProxyDependsOn(t *testing.T) {
+	proxyConfig := &ecs.ProxyConfiguration{ContainerName: aws.String("envoy")}
+
+	dependsOn := convertToProxyDependsOn(proxyConfig, aws.String("web"))
+	assert.Equal(t, []string{appMeshProxyServiceName}, dependsOn)
+
+	assert.Nil(t, convertToProxyDependsOn(proxyConfig, aws.String("envoy")))
+	assert.Nil(t, convertToProxyDependsOn(nil, aws.String("web")))
+}
+
+func TestConvertToProxyDependsOn_CustomContainerName(t *testing.T) {
+	proxyConfig := &ecs.ProxyConfiguration{ContainerName: aws.String("app-mesh-proxy")}
+
+	dependsOn := convertToProxyDependsOn(proxyConfig, aws.String("web"))
+	assert.Equal(t, []string{"app-mesh-proxy"}, dependsOn)
+
+	assert.Nil(t, convertToProxyDependsOn(proxyConfig, aws.String("app-mesh-proxy")))
+}
+
+func TestConvertToVolumesFrom(t *testing.T) {
+	input := []*ecs.VolumeFrom{
+		{
+			SourceContainer: aws.String("web"),
+		},
+		{
+			SourceContainer: aws.String("data"),
+			ReadOnly:        aws.Bool(true),
+		},
+	}
+
+	expected := []string{"web", "data:ro"}
+	actual := convertToVolumesFrom(input)
+
+	assert.Equal(t, expected, actual)
+}
+
+func TestConvertToComposeService_VolumesFromLabel(t *testing.T) {
+	containerDef := &ecs.ContainerDefinition{
+		Name: aws.String("app"),
+		VolumesFrom: []*ecs.VolumeFrom{
+			{SourceContainer: aws.String("web")},
+			{SourceContainer: aws.String("data"), ReadOnly: aws.Bool(true)},
+		},
+	}
+
+	service, err := convertToComposeService(nil, containerDef)
+	assert.NoError(t, err, "Unexpected error converting ComposeService")
+	assert.Equal(t, "web,data:ro", service.Labels[volumesFromLabel])
+}
+func TestConvertToComposeProject(t *testing.T) {
+	taskDefinition := &ecs.TaskDefinition{
+		NetworkMode: aws.String(ecs.NetworkModeAwsvpc),
+		Volumes: []*ecs.Volume{
+			{
+				Name: aws.String("data"),
+				DockerVolumeConfiguration: &ecs.DockerVolumeConfiguration{
+					Driver: aws.String("local"),
+				},
+			},
+		},
+		ProxyConfiguration: &ecs.ProxyConfiguration{
+			ContainerName: aws.String("envoy"),
+		},
+		ContainerDefinitions: []*ecs.ContainerDefinition{
+			{
+				Name:  aws.String("web"),
+				Image: aws.String("nginx"),
+				MountPoints: []*ecs.MountPoint{
+					{SourceVolume: aws.String("data"), ContainerPath: aws.String("/var/lib/data")},
+				},
+				Secrets: []*ecs.Secret{
+					{Name: aws.String("db_password"), ValueFrom: aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:db_password")},
+				},
+			},
+		},
+	}
+
+	project, err := convertToComposeProject(taskDefinition)
+	assert.NoError(t, err, "Unexpected error converting TaskDefinition to a compose project")
+	assert.Len(t, project.Services, 2, "expected the web service plus the Envoy proxy sidecar")
+	assert.Contains(t, project.Volumes, "data")
+	assert.Contains(t, project.Secrets, "db_password")
+	assert.Contains(t, project.Networks, awsvpcNetworkName)
+}
+
+func TestConvertToComposeProject_ErrorsOnNilTaskDefinition(t *testing.T) {
+	_, err := convertToComposeProject(nil)
+	assert.Error(t, err)
+}
+
+func TestConvertToComposeProject_NoDuplicateProxyServiceWhenExplicitlyDeclared(t *testing.T) {
+	taskDefinition := &ecs.TaskDefinition{
+		ProxyConfiguration: &ecs.ProxyConfiguration{
+			ContainerName: aws.String("envoy"),
+		},
+		ContainerDefinitions: []*ecs.ContainerDefinition{
+			{Name: aws.String("web"), Image: aws.String("nginx")},
+			{Name: aws.String("envoy"), Image: aws.String("my-custom-envoy:latest")},
+		},
+	}
+
+	project, err := convertToComposeProject(taskDefinition)
+	assert.NoError(t, err, "Unexpected error converting TaskDefinition to a compose project")
+	assert.Len(t, project.Services, 2, "the user-declared envoy container must not be duplicated by the synthesized proxy service")
+}
+
+func TestConvertToComposeProject_ProxyDependsOnMatchesCustomContainerName(t *testing.T) {
+	taskDefinition := &ecs.TaskDefinition{
+		ProxyConfiguration: &ecs.ProxyConfiguration{
+			ContainerName: aws.String("app-mesh-proxy"),
+		},
+		ContainerDefinitions: []*ecs.ContainerDefinition{
+			{Name: aws.String("web"), Image: aws.String("nginx")},
+			{Name: aws.String("app-mesh-proxy"), Image: aws.String("my-custom-envoy:latest")},
+		},
+	}
+
+	project, err := convertToComposeProject(taskDefinition)
+	assert.NoError(t, err, "Unexpected error converting TaskDefinition to a compose project")
+	assert.Len(t, project.Services, 2, "the user-declared proxy container must not be duplicated by the synthesized proxy service")
+
+	var web *composeV3.ServiceConfig
+	for i, svc := range project.Services {
+		if svc.Name == "web" {
+			web = &project.Services[i]
+		}
+	}
+	assert.NotNil(t, web, "expected a web service in the generated project")
+	assert.Equal(t, []string{"app-mesh-proxy"}, web.DependsOn, "web must depend on the proxy container by its actual name, not the envoy default")
+}
+
+func TestHasContainerDefinition(t *testing.T) {
+	containerDefs := []*ecs.ContainerDefinition{
+		{Name: aws.String("web")},
+		{Name: aws.String("envoy")},
+	}
+
+	assert.True(t, hasContainerDefinition(containerDefs, &ecs.ProxyConfiguration{ContainerName: aws.String("envoy")}))
+	assert.False(t, hasContainerDefinition(containerDefs, &ecs.ProxyConfiguration{ContainerName: aws.String("missing")}))
+	assert.False(t, hasContainerDefinition(containerDefs, nil))
+}